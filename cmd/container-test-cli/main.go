@@ -1,71 +1,215 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"container-test-cli/internal/cli"
 	"container-test-cli/internal/config"
+	"container-test-cli/internal/engine"
 	"container-test-cli/internal/output"
 	"container-test-cli/internal/runner"
+	"container-test-cli/internal/watch"
 )
 
-// runTests executes all test cases sequentially, respecting fail-fast behavior.
+// buildEngine resolves the configured container engine backend, loading the
+// optional top-level `kubernetes:` config needed by the kubectl backend.
+func buildEngine(cfg *cli.CliConfig) engine.Engine {
+	kubeCfg, err := config.LoadKubernetesConfig(cfg.ConfigPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load kubernetes config: %v\n", err)
+	}
+	return engine.New(cfg.Engine, kubeCfg)
+}
+
+// runTests executes all test cases, respecting fail-fast behavior, across
+// cfg.Parallel concurrent workers (defaulting to 1, i.e. sequentially).
 // Returns a slice of test results and the total number of failed tests.
 func runTests(cfg *cli.CliConfig, tests []config.TestCase) ([]runner.Result, int) {
 	enableColor := output.ShouldUseColor()
-	results := make([]runner.Result, 0, len(tests))
 	failures := 0
 
-	for idx, testCase := range tests {
-		name := testCase.ResolveName(idx)
-		fmt.Printf("==> %s\n", name)
-
-		res := runner.RunSingleTest(testCase, cfg.Engine, cfg.Image, cfg.DefaultTimeout, cfg.Debug, cfg.DryRun)
+	opts := runner.RunOptions{
+		Parallel:       cfg.Parallel,
+		FailFast:       cfg.FailFast,
+		Engine:         buildEngine(cfg),
+		Image:          cfg.Image,
+		DefaultTimeout: cfg.DefaultTimeout,
+		Debug:          cfg.Debug,
+		DryRun:         cfg.DryRun,
+	}
 
-		if !cfg.DryRun {
-			output.PrintResult(res, enableColor)
-			if len(res.Failures) > 0 {
-				failures++
-			}
+	results := runner.RunAll(context.Background(), tests, opts, func(idx int, res runner.Result) {
+		if cfg.DryRun {
+			return
 		}
+		output.PrintResult(res, enableColor)
+		if len(res.Failures) > 0 {
+			failures++
+		}
+	})
+
+	if cfg.FailFast && failures > 0 {
+		fmt.Println("Stopping due to fail-fast")
+	}
+
+	return results, failures
+}
+
+// writeReportFormat dispatches a -report flag's spec to the matching output writer.
+func writeReportFormat(spec cli.ReportSpec, results []runner.Result) error {
+	switch spec.Format {
+	case "json":
+		return output.WriteReport(spec.Path, results)
+	case "junit":
+		return output.WriteJUnit(spec.Path, results)
+	case "tap":
+		return output.WriteTAP(spec.Path, results)
+	default:
+		return fmt.Errorf("unknown report format %q (want json, junit, or tap)", spec.Format)
+	}
+}
+
+// startServices loads the suite's optional `services:` section and, if any
+// are declared, starts them on a dedicated network and arranges for them to
+// be stopped when the returned cleanup func runs. It installs a SIGINT/
+// SIGTERM handler so background containers are still removed on Ctrl-C.
+// tests is returned with `--network` injected for every test that `needs` a
+// service, and sorted so depends_on predecessors run first.
+func startServices(cfg *cli.CliConfig, tests config.TestList) (config.TestList, func(), error) {
+	services, err := config.LoadServices(cfg.ConfigPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load services: %w", err)
+	}
+
+	sorted, err := runner.SortByDependsOn(tests)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(services) == 0 {
+		return sorted, func() {}, nil
+	}
 
-		results = append(results, res)
+	manager := runner.NewServiceManager(cfg.Engine, fmt.Sprintf("container-test-net-%d", os.Getpid()))
 
-		if cfg.FailFast && failures > 0 {
-			fmt.Println("Stopping due to fail-fast")
-			break
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		if _, ok := <-sigCh; ok {
+			manager.StopAll(context.Background())
+			os.Exit(130)
 		}
+	}()
+
+	if err := manager.StartAll(context.Background(), services); err != nil {
+		signal.Stop(sigCh)
+		return nil, nil, fmt.Errorf("failed to start services: %w", err)
 	}
 
-	return results, failures
+	cleanup := func() {
+		signal.Stop(sigCh)
+		close(sigCh)
+		manager.StopAll(context.Background())
+	}
+
+	return runner.InjectServiceNetwork(sorted, manager.Network()), cleanup, nil
 }
 
-// main is the entry point for the container test CLI. It parses command-line flags,
-// loads test definitions, executes all tests, optionally writes a JSON report,
-// and exits with an appropriate status code.
-func main() {
-	cfg := cli.ParseFlags()
+// run executes one invocation of the CLI and returns its process exit code,
+// so cleanup (e.g. stopping services) always runs via defer regardless of
+// which branch below produces the final status.
+func run(cfg *cli.CliConfig) int {
+	if cfg.Watch {
+		err := watch.Run(cfg, func(tests config.TestList) []runner.Result {
+			results, _ := runTests(cfg, tests)
+			return results
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		return 0
+	}
 
-	tests, err := config.LoadTests(cfg.ConfigPath)
+	tests, fixtures, err := config.LoadTests(cfg.ConfigPath, cfg.Vars)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: failed to load tests: %v\n", err)
-		os.Exit(2)
+		return 2
+	}
+
+	eng := buildEngine(cfg)
+
+	if len(fixtures.Setup) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DefaultTimeout)*time.Second)
+		_, _, setupFailures := runner.RunHooks(ctx, eng, cfg.Image, fixtures.Setup, "", nil, cfg.Debug)
+		cancel()
+		if len(setupFailures) > 0 {
+			for _, f := range setupFailures {
+				fmt.Fprintf(os.Stderr, "suite_setup: %s\n", f)
+			}
+			return 2
+		}
+	}
+	if len(fixtures.Teardown) > 0 {
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DefaultTimeout)*time.Second)
+			defer cancel()
+			_, _, teardownFailures := runner.RunHooks(ctx, eng, cfg.Image, fixtures.Teardown, "", nil, cfg.Debug)
+			for _, f := range teardownFailures {
+				fmt.Fprintf(os.Stderr, "suite_teardown: %s\n", f)
+			}
+		}()
+	}
+
+	tests, cleanup, err := startServices(cfg, tests)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
 	}
+	defer cleanup()
 
 	results, failures := runTests(cfg, tests)
 
 	if cfg.JsonReport != "" {
 		if err := output.WriteReport(cfg.JsonReport, results); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: failed to write report: %v\n", err)
-			os.Exit(2)
+			return 2
 		}
 		fmt.Printf("Report written to %s\n", cfg.JsonReport)
 	}
 
+	if cfg.JunitReport != "" {
+		if err := output.WriteJUnit(cfg.JunitReport, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write junit report: %v\n", err)
+			return 2
+		}
+		fmt.Printf("Report written to %s\n", cfg.JunitReport)
+	}
+
+	for _, spec := range cfg.Reports {
+		if err := writeReportFormat(spec, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write %s report: %v\n", spec.Format, err)
+			return 2
+		}
+		fmt.Printf("Report written to %s\n", spec.Path)
+	}
+
 	if failures > 0 {
 		fmt.Printf("\nCompleted with %d failing test(s)\n", failures)
-		os.Exit(1)
+		return 1
 	}
 	fmt.Println("\nAll tests passed")
+	return 0
+}
+
+// main is the entry point for the container test CLI. It parses command-line flags,
+// loads test definitions, executes all tests, optionally writes a JSON report,
+// and exits with an appropriate status code.
+func main() {
+	os.Exit(run(cli.ParseFlags()))
 }
@@ -6,8 +6,66 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
+// ReportSpec names an output format and destination path for a `-report`
+// flag occurrence, e.g. `-report junit:report.xml`.
+type ReportSpec struct {
+	Format string
+	Path   string
+}
+
+// reportFlag implements flag.Value so `-report` can be passed more than once,
+// accumulating into Specs.
+type reportFlag struct {
+	specs *[]ReportSpec
+}
+
+func (r reportFlag) String() string { return "" }
+
+func (r reportFlag) Set(value string) error {
+	format, path, ok := strings.Cut(value, ":")
+	if !ok || format == "" || path == "" {
+		return fmt.Errorf("report must be in the form format:path (e.g. junit:report.xml)")
+	}
+	*r.specs = append(*r.specs, ReportSpec{Format: format, Path: path})
+	return nil
+}
+
+// watchPathFlag implements flag.Value so `-watch-path` can be passed more
+// than once, accumulating into a list of extra paths (e.g. image build
+// outputs) -watch mode should also observe.
+type watchPathFlag struct {
+	paths *[]string
+}
+
+func (w watchPathFlag) String() string { return "" }
+
+func (w watchPathFlag) Set(value string) error {
+	*w.paths = append(*w.paths, value)
+	return nil
+}
+
+// varFlag implements flag.Value so `-var` can be passed more than once,
+// accumulating into a map of template variables for ${name} interpolation.
+type varFlag struct {
+	vars *map[string]string
+}
+
+func (v varFlag) String() string { return "" }
+
+func (v varFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("var must be in the form key=value")
+	}
+	(*v.vars)[key] = val
+	return nil
+}
+
 // CliConfig holds the parsed command-line configuration for the application.
 type CliConfig struct {
 	ConfigPath     string
@@ -15,10 +73,26 @@ type CliConfig struct {
 	Engine         string
 	DefaultTimeout int
 	JsonReport     string
+	JunitReport    string
 	FailFast       bool
 	Debug          bool
 	DryRun         bool
 	ShowVersion    bool
+	Parallel       int
+	// Reports holds additional report formats requested via repeated
+	// `-report format:path` flags (e.g. junit, tap), alongside -json-report.
+	Reports []ReportSpec
+	Watch   bool
+	// WatchPaths names extra files -watch mode should observe alongside
+	// ConfigPath (e.g. image build outputs); a change to any of them triggers
+	// a full re-run instead of just the diffed changed/added tests.
+	WatchPaths []string
+	// VarsFile names a YAML file of template variables (key: value) used for
+	// ${name} interpolation; Vars holds the final merged variable set (CLI
+	// -var > VarsFile > the config's own top-level vars:, applied in
+	// config.LoadTests).
+	VarsFile string
+	Vars     map[string]string
 }
 
 // parseFlags parses command-line flags, validates required parameters, and returns the configuration.
@@ -28,13 +102,21 @@ func ParseFlags() *CliConfig {
 
 	flag.StringVar(&cfg.ConfigPath, "config", os.Getenv("CONTAINER_TEST_CONFIG"), "Path to YAML file describing tests")
 	flag.StringVar(&cfg.Image, "image", os.Getenv("CONTAINER_TEST_IMAGE"), "Image reference to run")
-	flag.StringVar(&cfg.Engine, "engine", env.EnvDefault("CONTAINER_TEST_ENGINE", "docker"), "Container engine CLI to use (docker, podman, ...)")
+	flag.StringVar(&cfg.Engine, "engine", env.EnvDefault("CONTAINER_TEST_ENGINE", "docker"), "Container engine backend to use (docker, podman, nerdctl, kubectl); services: only supports docker, podman, and nerdctl")
 	flag.IntVar(&cfg.DefaultTimeout, "default-timeout", env.EnvInt("CONTAINER_TEST_DEFAULT_TIMEOUT", 30), "Default timeout (seconds) for each test when not specified")
 	flag.StringVar(&cfg.JsonReport, "json-report", os.Getenv("CONTAINER_TEST_JSON_REPORT"), "Write a JSON report to the given path")
+	flag.StringVar(&cfg.JunitReport, "junit-report", os.Getenv("CONTAINER_TEST_JUNIT_REPORT"), "Write a JUnit XML report to the given path (shorthand for -report junit:path)")
 	flag.BoolVar(&cfg.FailFast, "fail-fast", env.EnvBool("CONTAINER_TEST_FAIL_FAST", false), "Stop on first failure")
 	flag.BoolVar(&cfg.Debug, "debug", env.EnvBool("CONTAINER_TEST_DEBUG", false), "Print commands before execution")
 	flag.BoolVar(&cfg.DryRun, "dry-run", env.EnvBool("CONTAINER_TEST_DRY_RUN", false), "Print commands without executing")
 	flag.BoolVar(&cfg.ShowVersion, "version", false, "Print version and exit")
+	flag.IntVar(&cfg.Parallel, "parallel", env.EnvInt("CONTAINER_TEST_PARALLEL", 1), "Number of tests to run concurrently")
+	flag.Var(reportFlag{specs: &cfg.Reports}, "report", "Write an additional report as format:path (json, junit, or tap); may be repeated")
+	flag.BoolVar(&cfg.Watch, "watch", env.EnvBool("CONTAINER_TEST_WATCH", false), "Watch the config file and re-run affected tests on change")
+	flag.Var(watchPathFlag{paths: &cfg.WatchPaths}, "watch-path", "Extra path for -watch to observe (e.g. an image build output); triggers a full re-run on change (may be repeated)")
+	flag.StringVar(&cfg.VarsFile, "vars-file", os.Getenv("CONTAINER_TEST_VARS_FILE"), "YAML file of template variables (key: value) for ${name} interpolation")
+	cliVars := map[string]string{}
+	flag.Var(varFlag{vars: &cliVars}, "var", "Set a template variable as key=value for ${name} interpolation (may be repeated); overrides -vars-file and the config's own vars:")
 
 	flag.Parse()
 
@@ -49,5 +131,26 @@ func ParseFlags() *CliConfig {
 		os.Exit(2)
 	}
 
+	if cfg.Parallel < 1 {
+		fmt.Fprintf(os.Stderr, "Warning: -parallel must be >= 1, got %d; using 1\n", cfg.Parallel)
+		cfg.Parallel = 1
+	}
+
+	cfg.Vars = map[string]string{}
+	if cfg.VarsFile != "" {
+		data, err := os.ReadFile(cfg.VarsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read -vars-file: %v\n", err)
+			os.Exit(2)
+		}
+		if err := yaml.Unmarshal(data, &cfg.Vars); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to parse -vars-file: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	for k, v := range cliVars {
+		cfg.Vars[k] = v
+	}
+
 	return cfg
 }
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -60,6 +62,119 @@ type ExpectBlock struct {
 	StdoutRegex    string          `yaml:"stdout_regex" json:"stdout_regex,omitempty"`
 	StderrRegex    string          `yaml:"stderr_regex" json:"stderr_regex,omitempty"`
 	TimeoutSeconds *int            `yaml:"timeout_seconds" json:"timeout_seconds,omitempty"`
+	// Assert holds expr-lang boolean expressions evaluated against exit_code,
+	// stdout, stderr and duration_ms, e.g. `exit_code == 0 && contains(stdout, "ready")`.
+	Assert StringOrSlice `yaml:"assert" json:"assert,omitempty"`
+	// Group holds a bin_op-combined sub-tree when this block was written as
+	// `{ bin_op: or, tests: [...] }` rather than a flat set of matchers. See
+	// UnmarshalYAML.
+	Group *ExpectGroup `yaml:"-" json:"group,omitempty"`
+
+	// FilesExist/FilesAbsent/FileContents/FileMode/User assert on in-container
+	// filesystem and process state rather than the test command's own output;
+	// runner evaluates them via a short-lived probe run.
+	FilesExist   StringOrSlice       `yaml:"files_exist" json:"files_exist,omitempty"`
+	FilesAbsent  StringOrSlice       `yaml:"files_absent" json:"files_absent,omitempty"`
+	FileContents FileContentsExpects `yaml:"file_contents" json:"file_contents,omitempty"`
+	FileMode     FileModeExpects     `yaml:"file_mode" json:"file_mode,omitempty"`
+	User         string              `yaml:"user" json:"user,omitempty"`
+}
+
+// FileContentsExpect asserts that a file inside the container contains each
+// of Contains as a substring.
+type FileContentsExpect struct {
+	Path     string        `yaml:"path" json:"path"`
+	Contains StringOrSlice `yaml:"contains" json:"contains,omitempty"`
+}
+
+// FileContentsExpects allows file_contents to be a single mapping or a list
+// of mappings.
+type FileContentsExpects []FileContentsExpect
+
+// UnmarshalYAML allows either a single file_contents mapping or a list of them.
+func (f *FileContentsExpects) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.MappingNode:
+		var single FileContentsExpect
+		if err := value.Decode(&single); err != nil {
+			return err
+		}
+		*f = []FileContentsExpect{single}
+		return nil
+	case yaml.SequenceNode:
+		var list []FileContentsExpect
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		*f = list
+		return nil
+	default:
+		return fmt.Errorf("file_contents must be a mapping or a list of mappings")
+	}
+}
+
+// FileModeExpect asserts a file's octal permission bits, e.g. "0755".
+type FileModeExpect struct {
+	Path   string `yaml:"path" json:"path"`
+	Equals string `yaml:"equals" json:"equals"`
+}
+
+// FileModeExpects allows file_mode to be a single mapping or a list of mappings.
+type FileModeExpects []FileModeExpect
+
+// UnmarshalYAML allows either a single file_mode mapping or a list of them.
+func (f *FileModeExpects) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.MappingNode:
+		var single FileModeExpect
+		if err := value.Decode(&single); err != nil {
+			return err
+		}
+		*f = []FileModeExpect{single}
+		return nil
+	case yaml.SequenceNode:
+		var list []FileModeExpect
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		*f = list
+		return nil
+	default:
+		return fmt.Errorf("file_mode must be a mapping or a list of mappings")
+	}
+}
+
+// ExpectGroup combines several ExpectBlocks (or nested groups) with "and"/"or"
+// semantics, similar to kube-bench's test grouping.
+type ExpectGroup struct {
+	BinOp     string        `yaml:"bin_op" json:"bin_op"`
+	Tests     []ExpectBlock `yaml:"tests" json:"tests,omitempty"`
+	SubGroups []ExpectGroup `yaml:"sub_groups" json:"sub_groups,omitempty"`
+}
+
+// UnmarshalYAML accepts either the flat matcher shape or a `bin_op` group
+// shape (`{ bin_op: or, tests: [ {...}, {...} ] }`).
+func (e *ExpectBlock) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if value.Content[i].Value == "bin_op" {
+				var group ExpectGroup
+				if err := value.Decode(&group); err != nil {
+					return err
+				}
+				*e = ExpectBlock{Group: &group}
+				return nil
+			}
+		}
+	}
+
+	type plainExpectBlock ExpectBlock
+	var plain plainExpectBlock
+	if err := value.Decode(&plain); err != nil {
+		return err
+	}
+	*e = ExpectBlock(plain)
+	return nil
 }
 
 // ExitCodeExpect holds a parsed exit-code expression like ==0, >=1, !=0, <2.
@@ -147,13 +262,71 @@ type TestCase struct {
 	RunArgs    []string          `yaml:"run_args" json:"run_args,omitempty"`
 	Entrypoint *string           `yaml:"entrypoint" json:"entrypoint,omitempty"`
 	Timeout    *int              `yaml:"timeout_seconds" json:"timeout_seconds,omitempty"`
+	// Needs names top-level `services:` this test requires running before it
+	// starts; DependsOn names other tests (by name) that must run first.
+	Needs     []string `yaml:"needs" json:"needs,omitempty"`
+	DependsOn []string `yaml:"depends_on" json:"depends_on,omitempty"`
+	// Retry re-runs a failing test rather than reporting it failed outright,
+	// for suites with known-flaky commands (e.g. waiting on a slow service).
+	Retry *RetryPolicy `yaml:"retry" json:"retry,omitempty"`
+	// Setup runs, in order, before the main command; if any setup command
+	// fails the test is reported as "ERRORED" (rather than "FAILED") and the
+	// main command is skipped. Teardown always runs afterward, even if setup
+	// or the main command failed or timed out, using its own timeout.
+	Setup                  []CommandValue `yaml:"setup" json:"setup,omitempty"`
+	Teardown               []CommandValue `yaml:"teardown" json:"teardown,omitempty"`
+	TeardownTimeoutSeconds *int           `yaml:"teardown_timeout_seconds" json:"teardown_timeout_seconds,omitempty"`
+}
+
+// RetryPolicy controls how many times, and under what conditions, a test is
+// re-executed after a failing attempt. If On is empty, any failure triggers
+// a retry; otherwise only failures matching one of the named triggers
+// ("timeout", "exit_code", "stderr_regex") do.
+type RetryPolicy struct {
+	Max          int           `yaml:"max" json:"max"`
+	DelaySeconds int           `yaml:"delay_seconds" json:"delay_seconds,omitempty"`
+	On           StringOrSlice `yaml:"on" json:"on,omitempty"`
+}
+
+// ServiceReadyProbe defines how ServiceManager decides a service has become
+// ready to use: it re-runs Exec inside the running service container until
+// its stdout contains StdoutContains, or TimeoutSeconds elapses.
+type ServiceReadyProbe struct {
+	Exec           CommandValue `yaml:"exec" json:"exec,omitempty"`
+	StdoutContains string       `yaml:"stdout_contains" json:"stdout_contains,omitempty"`
+	TimeoutSeconds int          `yaml:"timeout_seconds" json:"timeout_seconds,omitempty"`
+}
+
+// Service describes a long-running background container, started before any
+// test that `needs` it and stopped once the run completes.
+type Service struct {
+	Name    string             `yaml:"name" json:"name"`
+	Image   string             `yaml:"image" json:"image"`
+	RunArgs []string           `yaml:"run_args" json:"run_args,omitempty"`
+	Env     map[string]string  `yaml:"env" json:"env,omitempty"`
+	Ready   *ServiceReadyProbe `yaml:"ready" json:"ready,omitempty"`
 }
 
 // TestList holds all parsed test cases.
 type TestList []TestCase
 
-// UnmarshalYAML supports a root sequence or {tests: []}.
+// SuiteFixtures holds commands that run once before/after the whole test
+// list, regardless of individual test outcomes.
+type SuiteFixtures struct {
+	Setup    []CommandValue
+	Teardown []CommandValue
+}
+
+// lastSuiteFixtures captures the suite_setup/suite_teardown blocks seen by
+// the most recent TestList.UnmarshalYAML call. TestList is a slice type, so
+// its Unmarshaler method has no way to return the fixtures alongside the
+// tests themselves; LoadTests reads this immediately afterward.
+var lastSuiteFixtures SuiteFixtures
+
+// UnmarshalYAML supports a root sequence, or a mapping with a 'tests' list
+// and optional 'suite_setup'/'suite_teardown' command lists.
 func (tl *TestList) UnmarshalYAML(value *yaml.Node) error {
+	lastSuiteFixtures = SuiteFixtures{}
 	if value.Kind == yaml.SequenceNode {
 		result := make([]TestCase, 0, len(value.Content))
 		if err := value.Decode(&result); err != nil {
@@ -164,26 +337,389 @@ func (tl *TestList) UnmarshalYAML(value *yaml.Node) error {
 	}
 	if value.Kind == yaml.MappingNode {
 		var wrapper struct {
-			Tests []TestCase `yaml:"tests"`
+			Tests         []TestCase     `yaml:"tests"`
+			SuiteSetup    []CommandValue `yaml:"suite_setup"`
+			SuiteTeardown []CommandValue `yaml:"suite_teardown"`
 		}
 		if err := value.Decode(&wrapper); err != nil {
 			return err
 		}
 		*tl = wrapper.Tests
+		lastSuiteFixtures = SuiteFixtures{Setup: wrapper.SuiteSetup, Teardown: wrapper.SuiteTeardown}
 		return nil
 	}
 	return fmt.Errorf("config must be a list of tests or a map containing a 'tests' key")
 }
 
-// LoadTests reads and parses the YAML test definitions.
-func LoadTests(path string) (TestList, error) {
+// LoadTests reads and parses the YAML test definitions, resolves any "@path"
+// file references, interpolates `${name}` / `${env:NAME}` references in
+// every command, env, workdir, run_args and expect string field, and returns
+// the suite's optional suite_setup/suite_teardown command lists alongside
+// the tests. overrideVars take precedence over the config file's own
+// top-level `vars:` map (e.g. values from -var or -vars-file), letting one
+// YAML file be reused across environments.
+func LoadTests(path string, overrideVars map[string]string) (TestList, SuiteFixtures, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, SuiteFixtures{}, err
 	}
 	var tests TestList
 	if err := yaml.Unmarshal(data, &tests); err != nil {
+		return nil, SuiteFixtures{}, err
+	}
+	fixtures := lastSuiteFixtures
+
+	if err := resolveFileRefs(tests, filepath.Dir(path)); err != nil {
+		return nil, SuiteFixtures{}, err
+	}
+
+	vars, err := loadVars(data)
+	if err != nil {
+		return nil, SuiteFixtures{}, err
+	}
+	for k, v := range overrideVars {
+		vars[k] = v
+	}
+
+	for i := range tests {
+		tests[i] = interpolateTestCase(tests[i], vars)
+	}
+	return tests, fixtures, nil
+}
+
+// resolveFileRefs rewrites every "@path" value in tests -- a `command`/`exec`
+// scalar, or a `stdout_contains`/`stderr_contains` entry, including those
+// nested inside a `bin_op` group -- with the contents of the referenced
+// file, resolved relative to baseDir (the YAML config file's directory).
+// This keeps large scripts and expected-output fixtures out of the YAML
+// while inline values keep working unchanged. The rewrite happens here,
+// after yaml.Unmarshal, rather than in CommandValue's own UnmarshalYAML,
+// since that method has no way to know the config file's directory.
+func resolveFileRefs(tests TestList, baseDir string) error {
+	for i := range tests {
+		exec, err := resolveCommandFileRef(tests[i].Exec, baseDir)
+		if err != nil {
+			return fmt.Errorf("test %q: exec: %w", tests[i].Name, err)
+		}
+		tests[i].Exec = exec
+
+		command, err := resolveCommandFileRef(tests[i].Command, baseDir)
+		if err != nil {
+			return fmt.Errorf("test %q: command: %w", tests[i].Name, err)
+		}
+		tests[i].Command = command
+
+		expect, err := resolveExpectBlockFileRefs(tests[i].Expect, baseDir)
+		if err != nil {
+			return fmt.Errorf("test %q: expect: %w", tests[i].Name, err)
+		}
+		tests[i].Expect = expect
+	}
+	return nil
+}
+
+// resolveExpectBlockFileRefs resolves "@path" references in e's
+// stdout_contains/stderr_contains, recursing into e.Group's children when e
+// is a bin_op group.
+func resolveExpectBlockFileRefs(e ExpectBlock, baseDir string) (ExpectBlock, error) {
+	if e.Group != nil {
+		group, err := resolveExpectGroupFileRefs(*e.Group, baseDir)
+		if err != nil {
+			return e, err
+		}
+		e.Group = &group
+		return e, nil
+	}
+
+	var err error
+	if e.StdoutContains, err = resolveStringOrSliceFileRefs(e.StdoutContains, baseDir); err != nil {
+		return e, fmt.Errorf("stdout_contains: %w", err)
+	}
+	if e.StderrContains, err = resolveStringOrSliceFileRefs(e.StderrContains, baseDir); err != nil {
+		return e, fmt.Errorf("stderr_contains: %w", err)
+	}
+	return e, nil
+}
+
+func resolveExpectGroupFileRefs(g ExpectGroup, baseDir string) (ExpectGroup, error) {
+	for i := range g.Tests {
+		resolved, err := resolveExpectBlockFileRefs(g.Tests[i], baseDir)
+		if err != nil {
+			return g, err
+		}
+		g.Tests[i] = resolved
+	}
+	for i := range g.SubGroups {
+		resolved, err := resolveExpectGroupFileRefs(g.SubGroups[i], baseDir)
+		if err != nil {
+			return g, err
+		}
+		g.SubGroups[i] = resolved
+	}
+	return g, nil
+}
+
+// resolveCommandFileRef replaces a scalar `sh -c "@path"` command's script
+// body with the contents of the referenced file. Argv-list commands are
+// left untouched.
+func resolveCommandFileRef(cmd CommandValue, baseDir string) (CommandValue, error) {
+	if len(cmd) != 3 || cmd[0] != "sh" || cmd[1] != "-c" || !strings.HasPrefix(cmd[2], "@") {
+		return cmd, nil
+	}
+	content, err := readFileRef(cmd[2], baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return CommandValue{"sh", "-c", content}, nil
+}
+
+// resolveStringOrSliceFileRefs replaces any "@path" entry in values with the
+// contents of the referenced file.
+func resolveStringOrSliceFileRefs(values StringOrSlice, baseDir string) (StringOrSlice, error) {
+	if values == nil {
+		return nil, nil
+	}
+	result := make(StringOrSlice, len(values))
+	for i, v := range values {
+		if !strings.HasPrefix(v, "@") {
+			result[i] = v
+			continue
+		}
+		content, err := readFileRef(v, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = content
+	}
+	return result, nil
+}
+
+// readFileRef reads the file named by an "@path" reference (path resolved
+// relative to baseDir unless it is already absolute).
+func readFileRef(ref, baseDir string) (string, error) {
+	path := strings.TrimPrefix(ref, "@")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", ref, err)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// loadVars reads the optional top-level `vars:` map used for ${name}
+// interpolation. Config files using the legacy root-sequence-of-tests shape
+// have no room for a `vars:` key, so loadVars returns an empty map for them.
+func loadVars(data []byte) (map[string]string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return map[string]string{}, nil
+	}
+
+	var wrapper struct {
+		Vars map[string]string `yaml:"vars"`
+	}
+	if err := root.Content[0].Decode(&wrapper); err != nil {
+		return nil, err
+	}
+	if wrapper.Vars == nil {
+		return map[string]string{}, nil
+	}
+	return wrapper.Vars, nil
+}
+
+var varRefPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// interpolate replaces ${name} and ${env:NAME} references in s with values
+// from vars or the process environment, in that order: vars already holds
+// the CLI/-vars-file/YAML `vars:` precedence chain merged together, so a
+// bare ${name} only falls through to os.Getenv as the last (process env)
+// tier. A reference with no match at any tier is left untouched, so a
+// typo'd variable name surfaces as literal text in the test output rather
+// than silently disappearing.
+func interpolate(s string, vars map[string]string) string {
+	return varRefPattern.ReplaceAllStringFunc(s, func(match string) string {
+		key := match[2 : len(match)-1]
+		if strings.HasPrefix(key, "env:") {
+			if v, ok := os.LookupEnv(key[len("env:"):]); ok {
+				return v
+			}
+			return match
+		}
+		if v, ok := vars[key]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(key); ok {
+			return v
+		}
+		return match
+	})
+}
+
+// interpolateTestCase returns tc with ${...} references resolved in its exec,
+// command, workdir, run_args, env values, expect string fields and
+// setup/teardown hooks. Other fields, such as entrypoint, are left as written.
+func interpolateTestCase(tc TestCase, vars map[string]string) TestCase {
+	tc.Exec = interpolateCommand(tc.Exec, vars)
+	tc.Command = interpolateCommand(tc.Command, vars)
+	tc.Workdir = interpolate(tc.Workdir, vars)
+	tc.RunArgs = interpolateStrings(tc.RunArgs, vars)
+	if tc.Env != nil {
+		env := make(map[string]string, len(tc.Env))
+		for k, v := range tc.Env {
+			env[k] = interpolate(v, vars)
+		}
+		tc.Env = env
+	}
+	tc.Expect = interpolateExpectBlock(tc.Expect, vars)
+	tc.Setup = interpolateCommands(tc.Setup, vars)
+	tc.Teardown = interpolateCommands(tc.Teardown, vars)
+	return tc
+}
+
+func interpolateCommands(hooks []CommandValue, vars map[string]string) []CommandValue {
+	if hooks == nil {
+		return nil
+	}
+	result := make([]CommandValue, len(hooks))
+	for i, h := range hooks {
+		result[i] = interpolateCommand(h, vars)
+	}
+	return result
+}
+
+func interpolateCommand(values CommandValue, vars map[string]string) CommandValue {
+	if values == nil {
+		return nil
+	}
+	result := make(CommandValue, len(values))
+	for i, v := range values {
+		result[i] = interpolate(v, vars)
+	}
+	return result
+}
+
+func interpolateStrings(values []string, vars map[string]string) []string {
+	if values == nil {
+		return nil
+	}
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = interpolate(v, vars)
+	}
+	return result
+}
+
+func interpolateStringOrSlice(values StringOrSlice, vars map[string]string) StringOrSlice {
+	if values == nil {
+		return nil
+	}
+	result := make(StringOrSlice, len(values))
+	for i, v := range values {
+		result[i] = interpolate(v, vars)
+	}
+	return result
+}
+
+func interpolateExpectBlock(e ExpectBlock, vars map[string]string) ExpectBlock {
+	if e.Group != nil {
+		group := interpolateExpectGroup(*e.Group, vars)
+		e.Group = &group
+		return e
+	}
+
+	e.StdoutContains = interpolateStringOrSlice(e.StdoutContains, vars)
+	e.StdoutNot = interpolateStringOrSlice(e.StdoutNot, vars)
+	e.StderrContains = interpolateStringOrSlice(e.StderrContains, vars)
+	e.StdoutRegex = interpolate(e.StdoutRegex, vars)
+	e.StderrRegex = interpolate(e.StderrRegex, vars)
+	e.Assert = interpolateStringOrSlice(e.Assert, vars)
+	e.FilesExist = interpolateStringOrSlice(e.FilesExist, vars)
+	e.FilesAbsent = interpolateStringOrSlice(e.FilesAbsent, vars)
+	for i := range e.FileContents {
+		e.FileContents[i].Path = interpolate(e.FileContents[i].Path, vars)
+		e.FileContents[i].Contains = interpolateStringOrSlice(e.FileContents[i].Contains, vars)
+	}
+	for i := range e.FileMode {
+		e.FileMode[i].Path = interpolate(e.FileMode[i].Path, vars)
+		e.FileMode[i].Equals = interpolate(e.FileMode[i].Equals, vars)
+	}
+	e.User = interpolate(e.User, vars)
+	return e
+}
+
+func interpolateExpectGroup(g ExpectGroup, vars map[string]string) ExpectGroup {
+	for i := range g.Tests {
+		g.Tests[i] = interpolateExpectBlock(g.Tests[i], vars)
+	}
+	for i := range g.SubGroups {
+		g.SubGroups[i] = interpolateExpectGroup(g.SubGroups[i], vars)
+	}
+	return g
+}
+
+// KubernetesConfig configures the kubectl-based engine: which namespace and
+// service account ephemeral test Pods run under, and their image pull
+// policy. It mirrors the top-level `kubernetes:` YAML block.
+type KubernetesConfig struct {
+	Namespace       string `yaml:"namespace" json:"namespace,omitempty"`
+	ServiceAccount  string `yaml:"service_account" json:"service_account,omitempty"`
+	ImagePullPolicy string `yaml:"image_pull_policy" json:"image_pull_policy,omitempty"`
+}
+
+// LoadKubernetesConfig reads the optional top-level `kubernetes:` section.
+// It returns nil if the section is absent, or if the config uses the legacy
+// root-sequence-of-tests shape that has no room for it.
+func LoadKubernetesConfig(path string) (*KubernetesConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var wrapper struct {
+		Kubernetes *KubernetesConfig `yaml:"kubernetes"`
+	}
+	if err := root.Content[0].Decode(&wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper.Kubernetes, nil
+}
+
+// LoadServices reads the optional top-level `services:` section describing
+// shared background containers to start before tests run and stop after.
+// Config files using the legacy root-sequence-of-tests shape have no room
+// for a `services:` key, so LoadServices returns an empty slice for them.
+func LoadServices(path string) ([]Service, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var wrapper struct {
+		Services []Service `yaml:"services"`
+	}
+	if err := root.Content[0].Decode(&wrapper); err != nil {
 		return nil, err
 	}
-	return tests, nil
+	return wrapper.Services, nil
 }
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpectBlockUnmarshalPlain(t *testing.T) {
+	var e ExpectBlock
+	if err := yaml.Unmarshal([]byte(`stdout_contains: ready`), &e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Group != nil {
+		t.Fatal("expected a plain block to have no Group")
+	}
+	if len(e.StdoutContains) != 1 || e.StdoutContains[0] != "ready" {
+		t.Fatalf("expected stdout_contains: [ready], got %v", e.StdoutContains)
+	}
+}
+
+func TestExpectBlockUnmarshalBinOpGroup(t *testing.T) {
+	yamlDoc := `
+bin_op: or
+tests:
+  - stdout_contains: a
+  - stdout_contains: b
+`
+	var e ExpectBlock
+	if err := yaml.Unmarshal([]byte(yamlDoc), &e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Group == nil {
+		t.Fatal("expected a bin_op block to populate Group")
+	}
+	if e.Group.BinOp != "or" {
+		t.Fatalf("expected bin_op \"or\", got %q", e.Group.BinOp)
+	}
+	if len(e.Group.Tests) != 2 {
+		t.Fatalf("expected 2 child tests, got %d", len(e.Group.Tests))
+	}
+	if len(e.StdoutContains) != 0 {
+		t.Fatalf("expected the flat matcher fields to stay zero-valued on a group block, got %v", e.StdoutContains)
+	}
+}
+
+func TestExpectBlockUnmarshalNestedSubGroups(t *testing.T) {
+	yamlDoc := `
+bin_op: and
+sub_groups:
+  - bin_op: or
+    tests:
+      - stdout_contains: a
+`
+	var e ExpectBlock
+	if err := yaml.Unmarshal([]byte(yamlDoc), &e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Group == nil || len(e.Group.SubGroups) != 1 {
+		t.Fatalf("expected one nested sub_group, got %+v", e.Group)
+	}
+	if e.Group.SubGroups[0].BinOp != "or" {
+		t.Fatalf("expected nested bin_op \"or\", got %q", e.Group.SubGroups[0].BinOp)
+	}
+}
+
+func TestInterpolateResolvesFromVars(t *testing.T) {
+	got := interpolate("${name}", map[string]string{"name": "alpine"})
+	if got != "alpine" {
+		t.Fatalf("expected \"alpine\", got %q", got)
+	}
+}
+
+func TestInterpolateFallsBackToProcessEnv(t *testing.T) {
+	t.Setenv("CONTAINER_TEST_FOO", "from-env")
+	got := interpolate("${CONTAINER_TEST_FOO}", map[string]string{})
+	if got != "from-env" {
+		t.Fatalf("expected a bare ${name} absent from vars to fall back to os.Getenv, got %q", got)
+	}
+}
+
+func TestInterpolateEnvPrefixAlwaysReadsProcessEnv(t *testing.T) {
+	t.Setenv("CONTAINER_TEST_BAR", "env-value")
+	got := interpolate("${env:CONTAINER_TEST_BAR}", map[string]string{"CONTAINER_TEST_BAR": "vars-value"})
+	if got != "env-value" {
+		t.Fatalf("expected ${env:NAME} to bypass vars and read the process env, got %q", got)
+	}
+}
+
+func TestInterpolateUnmatchedReferenceLeftLiteral(t *testing.T) {
+	got := interpolate("${does_not_exist}", map[string]string{})
+	if got != "${does_not_exist}" {
+		t.Fatalf("expected an unmatched reference to be left untouched, got %q", got)
+	}
+}
+
+func TestLoadVarsOverrideTakesPrecedenceOverYAMLVars(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/suite.yaml"
+	yamlDoc := `
+vars:
+  image: from-yaml
+tests:
+  - name: check
+    command: echo ${image}
+`
+	if err := os.WriteFile(path, []byte(yamlDoc), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	// overrideVars represents a var set in both the YAML's own vars: map and
+	// via -var/-vars-file (merged by cli.ParseFlags before reaching LoadTests);
+	// the override must win.
+	tests, _, err := LoadTests(path, map[string]string{"image": "from-override"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := tests[0].Command[2]; got != "echo from-override" {
+		t.Fatalf("expected the -var/-vars-file override to win over the YAML vars:, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+// Package engine abstracts the container backend a test is run against, so
+// runner can execute the same config.TestCase through docker, podman,
+// nerdctl, or a Kubernetes cluster without branching on the backend name.
+package engine
+
+import (
+	"context"
+
+	"container-test-cli/internal/config"
+)
+
+// RunSpec describes a single container invocation, independent of which
+// backend actually executes it.
+type RunSpec struct {
+	Image      string
+	Command    []string
+	Workdir    string
+	Env        map[string]string
+	RunArgs    []string
+	Entrypoint *string
+}
+
+// Result holds the captured output of a Run or Inspect call. ExitCode is
+// only meaningful when the process started and exited on its own; errors
+// starting the process (backend CLI missing, context deadline, ...) are
+// reported via the error return instead.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Engine runs containers on behalf of the test runner. Implementations wrap
+// a specific backend CLI (or API); runner never shells out directly.
+type Engine interface {
+	// Name identifies the backend for logging and -engine flag matching
+	// (e.g. "docker", "podman", "nerdctl", "kubectl").
+	Name() string
+	// Run executes spec as a one-shot, removed-on-exit container run,
+	// honoring ctx's deadline and cancellation. A non-zero exit from the
+	// command itself is reported via Result.ExitCode with a nil error;
+	// the error return is for failures to start or complete the run at all
+	// (including ctx's deadline being exceeded).
+	Run(ctx context.Context, spec RunSpec) (Result, error)
+	// Pull fetches image ahead of a Run, so a slow first pull doesn't hide
+	// inside a test's own timeout.
+	Pull(ctx context.Context, image string) error
+	// Inspect runs a short shell script inside image via a throwaway
+	// container and returns its stdout, for in-container filesystem/process
+	// assertions (files_exist, file_mode, user, ...).
+	Inspect(ctx context.Context, image, script string) (string, error)
+	// Available reports whether the backend's CLI (and, for remote backends,
+	// the cluster it talks to) is reachable.
+	Available(ctx context.Context) bool
+	// Describe renders spec as a human-readable command line for -dry-run
+	// and -debug output. It need not be the literal argv Run executes.
+	Describe(spec RunSpec) string
+}
+
+// New resolves name to an Engine implementation. kubeCfg configures the
+// Kubernetes backend (namespace, service account, image pull policy) and is
+// ignored by every other name. Unknown names fall back to a generic
+// docker-compatible CLI engine, matching the long-standing behavior of
+// simply shelling out to "-engine" verbatim.
+func New(name string, kubeCfg *config.KubernetesConfig) Engine {
+	switch name {
+	case "kubectl":
+		return kubectlEngine{cfg: kubeCfg}
+	default:
+		return genericCLIEngine{name: name}
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// genericCLIEngine drives any docker-compatible CLI (docker, podman,
+// nerdctl, ...) by shelling out to its argv-compatible `run` subcommand.
+type genericCLIEngine struct {
+	name string
+}
+
+func (e genericCLIEngine) Name() string { return e.name }
+
+func (e genericCLIEngine) buildRunArgs(spec RunSpec) []string {
+	args := []string{e.name, "run", "--rm"}
+	args = append(args, spec.RunArgs...)
+	if spec.Entrypoint != nil {
+		args = append(args, "--entrypoint", *spec.Entrypoint)
+	}
+	for k, v := range spec.Env {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
+	}
+	if spec.Workdir != "" {
+		args = append(args, "-w", spec.Workdir)
+	}
+	args = append(args, spec.Image)
+	args = append(args, spec.Command...)
+	return args
+}
+
+func (e genericCLIEngine) Run(ctx context.Context, spec RunSpec) (Result, error) {
+	args := e.buildRunArgs(spec)
+	return e.exec(ctx, args)
+}
+
+func (e genericCLIEngine) exec(ctx context.Context, args []string) (Result, error) {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+	err := cmd.Run()
+
+	result := Result{Stdout: stdoutBuf.String(), Stderr: stderrBuf.String()}
+	if err == nil {
+		return result, nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return result, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil
+	}
+	return result, err
+}
+
+func (e genericCLIEngine) Pull(ctx context.Context, image string) error {
+	return exec.CommandContext(ctx, e.name, "pull", image).Run()
+}
+
+func (e genericCLIEngine) Inspect(ctx context.Context, image, script string) (string, error) {
+	args := []string{e.name, "run", "--rm", "--entrypoint", "sh", image, "-c", script}
+	result, err := e.exec(ctx, args)
+	return result.Stdout, err
+}
+
+func (e genericCLIEngine) Available(ctx context.Context) bool {
+	return exec.CommandContext(ctx, e.name, "version").Run() == nil
+}
+
+func (e genericCLIEngine) Describe(spec RunSpec) string {
+	return strings.Join(e.buildRunArgs(spec), " ")
+}
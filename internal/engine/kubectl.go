@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"container-test-cli/internal/config"
+)
+
+// kubectlEngine runs each test as an ephemeral, auto-removed Pod via
+// `kubectl run ... --rm -i --restart=Never`, so the same test suite can
+// target a real Kubernetes cluster instead of a local docker-compatible CLI.
+type kubectlEngine struct {
+	cfg *config.KubernetesConfig
+}
+
+func (e kubectlEngine) Name() string { return "kubectl" }
+
+// podName returns a unique name for the ephemeral Pod kubectl run creates;
+// kubectl also names the Pod's sole container after it by default.
+func (e kubectlEngine) podName() string {
+	return fmt.Sprintf("container-test-%d", time.Now().UnixNano())
+}
+
+func (e kubectlEngine) buildRunArgs(name string, spec RunSpec) []string {
+	args := []string{"kubectl", "run", name, "--rm", "-i", "--restart=Never", "--image=" + spec.Image}
+	if e.cfg != nil && e.cfg.Namespace != "" {
+		args = append(args, "--namespace="+e.cfg.Namespace)
+	}
+	if e.cfg != nil && e.cfg.ImagePullPolicy != "" {
+		args = append(args, "--image-pull-policy="+e.cfg.ImagePullPolicy)
+	}
+	if overrides := e.buildOverrides(name, spec.Workdir); overrides != "" {
+		args = append(args, "--overrides="+overrides)
+	}
+	for k, v := range spec.Env {
+		args = append(args, "--env="+k+"="+v)
+	}
+	args = append(args, spec.RunArgs...)
+
+	command := spec.Command
+	if spec.Entrypoint != nil {
+		command = append([]string{*spec.Entrypoint}, command...)
+	}
+	if len(command) > 0 {
+		args = append(args, "--command", "--")
+		args = append(args, command...)
+	}
+	return args
+}
+
+// buildOverrides assembles the single --overrides JSON document kubectl run
+// accepts, merging the configured service account (if any) with workdir,
+// which kubectl run has no dedicated flag for. Returns "" if nothing needs
+// overriding.
+func (e kubectlEngine) buildOverrides(containerName, workdir string) string {
+	spec := map[string]interface{}{}
+	if e.cfg != nil && e.cfg.ServiceAccount != "" {
+		spec["serviceAccountName"] = e.cfg.ServiceAccount
+	}
+	if workdir != "" {
+		spec["containers"] = []map[string]interface{}{
+			{"name": containerName, "workingDir": workdir},
+		}
+	}
+	if len(spec) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func (e kubectlEngine) Run(ctx context.Context, spec RunSpec) (Result, error) {
+	return e.exec(ctx, e.buildRunArgs(e.podName(), spec))
+}
+
+func (e kubectlEngine) exec(ctx context.Context, args []string) (Result, error) {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+	err := cmd.Run()
+
+	result := Result{Stdout: stdoutBuf.String(), Stderr: stderrBuf.String()}
+	if err == nil {
+		return result, nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return result, ctx.Err()
+	}
+	// kubectl run --rm -i exits with the Pod's own exit code, so an
+	// *exec.ExitError carries the test command's exit status; surface it via
+	// Result.ExitCode rather than treating it as a start failure.
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil
+	}
+	return result, err
+}
+
+func (e kubectlEngine) Pull(ctx context.Context, image string) error {
+	// kubectl has no standalone image-pull command; the kubelet pulls lazily
+	// as part of scheduling the Pod, governed by ImagePullPolicy.
+	return nil
+}
+
+func (e kubectlEngine) Inspect(ctx context.Context, image, script string) (string, error) {
+	spec := RunSpec{Image: image, Command: []string{"sh", "-c", script}}
+	result, err := e.exec(ctx, e.buildRunArgs(e.podName(), spec))
+	return result.Stdout, err
+}
+
+func (e kubectlEngine) Available(ctx context.Context) bool {
+	return exec.CommandContext(ctx, "kubectl", "cluster-info").Run() == nil
+}
+
+func (e kubectlEngine) Describe(spec RunSpec) string {
+	return strings.Join(e.buildRunArgs(e.podName(), spec), " ")
+}
@@ -2,8 +2,10 @@ package output
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"os"
+	"strings"
 
 	"container-test-cli/internal/runner"
 )
@@ -39,17 +41,119 @@ func WriteReport(path string, results []runner.Result) error {
 	return os.WriteFile(path, data, 0o644)
 }
 
-// PrintResult displays the test result status with color formatting.
-func PrintResult(res runner.Result) {
-	statusColored := Colorize(res.Status, res.Status, shouldUseColor())
-	name := res.Name
-	fmt.Printf("==> %s\n", name)
+// junitTestSuites is the root <testsuites> element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
 
-	fmt.Printf("   %s\n", statusColored)
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	SystemErr string        `xml:"system-err,omitempty"`
+}
+
+type junitSkipped struct{}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit emits a JUnit XML report (testsuites/testsuite/testcase) so CI
+// systems such as Jenkins, GitLab, and GitHub Actions test reporters can
+// consume results directly. Result.Status is mapped explicitly rather than
+// inferred from Failures alone, so a SKIPPED (fail-fast skip) or ERRORED
+// (setup failure) test renders as <skipped/>/<error> instead of a plain pass.
+func WriteJUnit(path string, results []runner.Result) error {
+	suite := junitSuite{Name: "container-test", Tests: len(results)}
+	for _, res := range results {
+		tc := junitCase{
+			Name:      res.Name,
+			Time:      fmt.Sprintf("%.3f", float64(res.DurationMs)/1000),
+			SystemOut: res.Stdout,
+			SystemErr: res.Stderr,
+		}
+		switch res.Status {
+		case "SKIPPED":
+			suite.Skipped++
+			tc.Skipped = &junitSkipped{}
+		case "ERRORED":
+			suite.Errors++
+			tc.Error = &junitFailure{
+				Message: strings.Join(res.Failures, "; "),
+				Text:    strings.Join(res.Failures, "\n"),
+			}
+		default:
+			if len(res.Failures) > 0 {
+				suite.Failures++
+				tc.Failure = &junitFailure{
+					Message: strings.Join(res.Failures, "; "),
+					Text:    strings.Join(res.Failures, "\n"),
+				}
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
 
-	for _, msg := range res.DebugMessages {
-		fmt.Printf("     [debug] %s\n", msg)
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	return os.WriteFile(path, data, 0o644)
+}
+
+// WriteTAP emits a TAP v13 report, attaching a YAML diagnostic block to each
+// failing test point so failure details survive in CI log viewers that only
+// render the TAP stream. Result.Status is mapped explicitly rather than
+// inferred from Failures alone, so a SKIPPED test gets a "# SKIP" directive
+// and an ERRORED test is still reported "not ok" even though a setup failure
+// leaves no main-command Failures of its own.
+func WriteTAP(path string, results []runner.Result) error {
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(results))
+	for i, res := range results {
+		status := "ok"
+		if len(res.Failures) > 0 || res.Status == "ERRORED" {
+			status = "not ok"
+		}
+		directive := ""
+		if res.Status == "SKIPPED" {
+			directive = " # SKIP"
+		}
+		fmt.Fprintf(&b, "%s %d - %s%s\n", status, i+1, res.Name, directive)
+		if status == "not ok" {
+			b.WriteString("  ---\n")
+			if len(res.Failures) > 0 {
+				fmt.Fprintf(&b, "  message: %q\n", strings.Join(res.Failures, "; "))
+			}
+			b.WriteString("  ...\n")
+		}
 	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// PrintResult displays the test result status with color formatting.
+func PrintResult(res runner.Result, enableColor bool) {
+	statusColored := Colorize(res.Status, res.Status, enableColor)
+	fmt.Printf("==> %s\n", res.Name)
+
+	fmt.Printf("   %s\n", statusColored)
 
 	for _, failure := range res.Failures {
 		fmt.Printf("     - %s\n", failure)
@@ -58,7 +162,7 @@ func PrintResult(res runner.Result) {
 	fmt.Println()
 }
 
-// shouldUseColor returns true if color output should be enabled.
-func shouldUseColor() bool {
+// ShouldUseColor returns true if color output should be enabled.
+func ShouldUseColor() bool {
 	return os.Getenv("NO_COLOR") == ""
 }
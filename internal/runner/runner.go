@@ -3,20 +3,40 @@ package runner
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/antonmedv/expr"
+
 	"container-test-cli/internal/config"
+	"container-test-cli/internal/engine"
 )
 
 // Result captures the outcome of running a single test.
 type Result struct {
-	Status   string   `json:"status"`
-	Name     string   `json:"name"`
+	Status     string   `json:"status"`
+	Name       string   `json:"name"`
+	Stdout     string   `json:"stdout"`
+	Stderr     string   `json:"stderr"`
+	ExitCode   *int     `json:"exit_code"`
+	Failures   []string `json:"failures,omitempty"`
+	DurationMs int64    `json:"duration_ms"`
+	// Attempts records every execution of a retried test, in order; it is
+	// only populated when the test case declares a retry policy.
+	Attempts []AttemptResult `json:"attempts,omitempty"`
+}
+
+// AttemptResult captures the outcome of a single execution of a test that
+// may be retried; see Result.Attempts.
+type AttemptResult struct {
 	Stdout   string   `json:"stdout"`
 	Stderr   string   `json:"stderr"`
 	ExitCode *int     `json:"exit_code"`
@@ -33,24 +53,6 @@ func firstNonEmpty(values ...string) string {
 	return ""
 }
 
-// BuildRunCommand assembles the engine run command with env, workdir, args, and entrypoint.
-func BuildRunCommand(engine, image string, cmd []string, workdir string, env map[string]string, runArgs []string, entrypoint *string) []string {
-	args := []string{engine, "run", "--rm"}
-	args = append(args, runArgs...)
-	if entrypoint != nil {
-		args = append(args, "--entrypoint", *entrypoint)
-	}
-	for k, v := range env {
-		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
-	}
-	if workdir != "" {
-		args = append(args, "-w", workdir)
-	}
-	args = append(args, image)
-	args = append(args, cmd...)
-	return args
-}
-
 // checkContains verifies that output contains all expected strings.
 func checkContains(output string, expectedStrings []string, outputName string) []string {
 	var failures []string
@@ -85,8 +87,158 @@ func checkRegex(output, pattern, outputName string) []string {
 	return nil
 }
 
+// Markers prefix each line of a probe script's stdout so evalProbeOutput can
+// parse it without ambiguity.
+const (
+	probeMarkerFilePresent  = "FILE_PRESENT"
+	probeMarkerFileContents = "FILE_CONTENTS"
+	probeMarkerFileMode     = "FILE_MODE"
+	probeMarkerUser         = "USER"
+)
+
+// needsProbe reports whether expect requires a follow-up in-container probe
+// run to check filesystem or process state beyond the primary command's output.
+func needsProbe(expect config.ExpectBlock) bool {
+	return len(expect.FilesExist) > 0 || len(expect.FilesAbsent) > 0 ||
+		len(expect.FileContents) > 0 || len(expect.FileMode) > 0 || expect.User != ""
+}
+
+// shellQuote wraps s in single quotes for safe interpolation into a POSIX sh script.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// buildProbeScript renders a POSIX sh script that reports the in-container
+// state expect needs to assert on, one machine-parseable marker line per
+// check, mirroring the config-audit pattern used by tools like kube-bench.
+func buildProbeScript(expect config.ExpectBlock) string {
+	var lines []string
+	for _, path := range expect.FilesExist {
+		lines = append(lines, fmt.Sprintf(`if [ -e %s ]; then echo "%s:1:%s"; else echo "%s:0:%s"; fi`,
+			shellQuote(path), probeMarkerFilePresent, path, probeMarkerFilePresent, path))
+	}
+	for _, path := range expect.FilesAbsent {
+		lines = append(lines, fmt.Sprintf(`if [ -e %s ]; then echo "%s:1:%s"; else echo "%s:0:%s"; fi`,
+			shellQuote(path), probeMarkerFilePresent, path, probeMarkerFilePresent, path))
+	}
+	for _, fc := range expect.FileContents {
+		lines = append(lines, fmt.Sprintf(`echo "%s:%s:$(cat %s 2>/dev/null | base64 | tr -d '\n')"`,
+			probeMarkerFileContents, fc.Path, shellQuote(fc.Path)))
+	}
+	for _, fm := range expect.FileMode {
+		lines = append(lines, fmt.Sprintf(`echo "%s:%s:$(stat -c '%%a' %s 2>/dev/null)"`,
+			probeMarkerFileMode, fm.Path, shellQuote(fm.Path)))
+	}
+	if expect.User != "" {
+		lines = append(lines, fmt.Sprintf(`echo "%s:$(id -un 2>/dev/null)"`, probeMarkerUser))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// samePermissions compares two permission-bit strings ("0755" vs "755")
+// numerically when possible, falling back to an exact string match.
+func samePermissions(a, b string) bool {
+	an, aerr := strconv.Atoi(a)
+	bn, berr := strconv.Atoi(b)
+	if aerr == nil && berr == nil {
+		return an == bn
+	}
+	return a == b
+}
+
+// evalProbeOutput parses a probe script's stdout and compares it against
+// expect's in-container assertions, returning any failures.
+func evalProbeOutput(expect config.ExpectBlock, probeOutput string) []string {
+	present := map[string]bool{}
+	contents := map[string]string{}
+	modes := map[string]string{}
+	var user string
+
+	for _, line := range strings.Split(probeOutput, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, probeMarkerFilePresent+":"):
+			if parts := strings.SplitN(line, ":", 3); len(parts) == 3 {
+				present[parts[2]] = parts[1] == "1"
+			}
+		case strings.HasPrefix(line, probeMarkerFileContents+":"):
+			if parts := strings.SplitN(line, ":", 3); len(parts) == 3 {
+				decoded, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(parts[2]))
+				contents[parts[1]] = string(decoded)
+			}
+		case strings.HasPrefix(line, probeMarkerFileMode+":"):
+			if parts := strings.SplitN(line, ":", 3); len(parts) == 3 {
+				modes[parts[1]] = strings.TrimSpace(parts[2])
+			}
+		case strings.HasPrefix(line, probeMarkerUser+":"):
+			if parts := strings.SplitN(line, ":", 2); len(parts) == 2 {
+				user = strings.TrimSpace(parts[1])
+			}
+		}
+	}
+
+	var failures []string
+	for _, path := range expect.FilesExist {
+		if !present[path] {
+			failures = append(failures, fmt.Sprintf("file does not exist: %s", path))
+		}
+	}
+	for _, path := range expect.FilesAbsent {
+		if present[path] {
+			failures = append(failures, fmt.Sprintf("file must not exist: %s", path))
+		}
+	}
+	for _, fc := range expect.FileContents {
+		body := contents[fc.Path]
+		failures = append(failures, checkContains(body, fc.Contains, fc.Path)...)
+	}
+	for _, fm := range expect.FileMode {
+		actual := modes[fm.Path]
+		if !samePermissions(actual, fm.Equals) {
+			failures = append(failures, fmt.Sprintf("%s mode %q != expected %q", fm.Path, actual, fm.Equals))
+		}
+	}
+	if expect.User != "" && user != expect.User {
+		failures = append(failures, fmt.Sprintf("user %q != expected %q", user, expect.User))
+	}
+	return failures
+}
+
+// collectProbeBlocks returns every leaf ExpectBlock in expect's tree
+// (including expect itself, if it is a leaf) that needsProbe, so callers can
+// build and run one combined in-container probe script up front rather than
+// probing per-block.
+func collectProbeBlocks(expect config.ExpectBlock) []config.ExpectBlock {
+	if expect.Group != nil {
+		return collectProbeBlocksInGroup(*expect.Group)
+	}
+	if needsProbe(expect) {
+		return []config.ExpectBlock{expect}
+	}
+	return nil
+}
+
+// collectProbeBlocksInGroup is collectProbeBlocks' recursive descent through
+// a bin_op group's children.
+func collectProbeBlocksInGroup(group config.ExpectGroup) []config.ExpectBlock {
+	var blocks []config.ExpectBlock
+	for _, block := range group.Tests {
+		blocks = append(blocks, collectProbeBlocks(block)...)
+	}
+	for _, sub := range group.SubGroups {
+		blocks = append(blocks, collectProbeBlocksInGroup(sub)...)
+	}
+	return blocks
+}
+
 // evalExpectations applies the expectBlock rules to collected outputs.
-func evalExpectations(expect config.ExpectBlock, stdout, stderr string, exitCode int) []string {
+// probeOutput is the combined in-container probe script's stdout (see
+// collectProbeBlocks), or nil if no block in the tree needed one.
+func evalExpectations(expect config.ExpectBlock, stdout, stderr string, exitCode int, durationMs int64, probeOutput *string) []string {
+	if expect.Group != nil {
+		return evalGroup(*expect.Group, stdout, stderr, exitCode, durationMs, probeOutput)
+	}
+
 	var failures []string
 
 	// Check exit code
@@ -107,16 +259,196 @@ func evalExpectations(expect config.ExpectBlock, stdout, stderr string, exitCode
 	failures = append(failures, checkContains(stderr, expect.StderrContains, "stderr")...)
 	failures = append(failures, checkRegex(stderr, expect.StderrRegex, "stderr")...)
 
+	// Check expr-lang assertions
+	failures = append(failures, checkAssertions(expect.Assert, stdout, stderr, exitCode, durationMs)...)
+
+	// Check in-container probe assertions (files_exist, file_mode, user, ...),
+	// if this block needed one and the probe actually ran.
+	if probeOutput != nil && needsProbe(expect) {
+		failures = append(failures, evalProbeOutput(expect, *probeOutput)...)
+	}
+
+	return failures
+}
+
+// evalGroup evaluates a bin_op-combined tree of expect blocks. An "or" group
+// passes if any child yields zero failures, summarizing the losing branches
+// rather than concatenating their failures; an "and" group requires every
+// child to pass and reports all of their failures. probeOutput is forwarded
+// to every child so nested blocks' files_exist/file_mode/user checks are
+// evaluated too, not just the top-level expect's.
+func evalGroup(group config.ExpectGroup, stdout, stderr string, exitCode int, durationMs int64, probeOutput *string) []string {
+	var childFailures [][]string
+	for _, block := range group.Tests {
+		childFailures = append(childFailures, evalExpectations(block, stdout, stderr, exitCode, durationMs, probeOutput))
+	}
+	for _, sub := range group.SubGroups {
+		childFailures = append(childFailures, evalGroup(sub, stdout, stderr, exitCode, durationMs, probeOutput))
+	}
+
+	if group.BinOp == "or" {
+		for _, failures := range childFailures {
+			if len(failures) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("none of %d alternatives matched", len(childFailures))}
+	}
+
+	var failures []string
+	for _, f := range childFailures {
+		failures = append(failures, f...)
+	}
+	return failures
+}
+
+// assertionEnv builds the evaluation context exposed to `assert` expressions:
+// exit_code, stdout, stderr, duration_ms, and a handful of helper functions.
+func assertionEnv(stdout, stderr string, exitCode int, durationMs int64) map[string]interface{} {
+	return map[string]interface{}{
+		"exit_code":   exitCode,
+		"stdout":      stdout,
+		"stderr":      stderr,
+		"duration_ms": int(durationMs),
+		"contains":    strings.Contains,
+		"matches": func(s, pattern string) bool {
+			ok, err := regexp.MatchString(pattern, s)
+			return err == nil && ok
+		},
+		"lines": func(s string) []string {
+			return strings.Split(strings.TrimRight(s, "\n"), "\n")
+		},
+		"json": func(s string) interface{} {
+			var v interface{}
+			_ = json.Unmarshal([]byte(s), &v)
+			return v
+		},
+	}
+}
+
+// checkAssertions compiles and runs each expr-lang assertion, recording a
+// failure for any expression that errors out or evaluates to false.
+func checkAssertions(exprs []string, stdout, stderr string, exitCode int, durationMs int64) []string {
+	if len(exprs) == 0 {
+		return nil
+	}
+	env := assertionEnv(stdout, stderr, exitCode, durationMs)
+
+	var failures []string
+	for _, src := range exprs {
+		program, err := expr.Compile(src, expr.Env(env), expr.AsBool())
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("assertion failed: %s (%v)", src, err))
+			continue
+		}
+		out, err := expr.Run(program, env)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("assertion failed: %s (%v)", src, err))
+			continue
+		}
+		if ok, _ := out.(bool); !ok {
+			failures = append(failures, fmt.Sprintf("assertion failed: %s", src))
+		}
+	}
 	return failures
 }
 
-// RunSingleTest executes a single container run and evaluates expectations.
-// If dryRun is true, it prints the command without executing it.
-func RunSingleTest(testCase config.TestCase, engine, image string, defaultTimeout int, debug, dryRun bool) Result {
+// RunSingleTest executes testCase.Setup hooks (if any), the test's main
+// command (retried per testCase.Retry), and testCase.Teardown hooks, in that
+// order. Teardown always runs, even if setup or the main command failed or
+// timed out, using its own timeout; a failing setup hook skips the main
+// command and reports "ERRORED" (distinct from "FAILED") since the test
+// itself was never actually exercised. If dryRun is true, setup/teardown
+// hooks are skipped entirely and the main command is only previewed; ctx
+// bounds every run in addition to the per-test timeout, so callers such as
+// RunAll can cancel in-flight runs (e.g. on fail-fast).
+func RunSingleTest(ctx context.Context, testCase config.TestCase, eng engine.Engine, image string, defaultTimeout int, debug, dryRun bool) Result {
 	if testCase.Skip {
 		return Result{Status: "SKIPPED", Name: firstNonEmpty(testCase.Name, "unnamed")}
 	}
+	if dryRun {
+		return runTestAttempts(ctx, testCase, eng, image, defaultTimeout, debug, dryRun)
+	}
+
+	name := firstNonEmpty(testCase.Name, "unnamed")
+
+	runTeardown := func() []string {
+		if len(testCase.Teardown) == 0 {
+			return nil
+		}
+		teardownTimeout := defaultTimeout
+		if testCase.TeardownTimeoutSeconds != nil {
+			teardownTimeout = *testCase.TeardownTimeoutSeconds
+		}
+		tctx, cancel := context.WithTimeout(ctx, time.Duration(teardownTimeout)*time.Second)
+		defer cancel()
+		_, _, failures := RunHooks(tctx, eng, image, testCase.Teardown, testCase.Workdir, testCase.Env, debug)
+		for i, f := range failures {
+			failures[i] = "teardown: " + f
+		}
+		return failures
+	}
+
+	if len(testCase.Setup) > 0 {
+		sctx, cancel := context.WithTimeout(ctx, time.Duration(defaultTimeout)*time.Second)
+		_, _, setupFailures := RunHooks(sctx, eng, image, testCase.Setup, testCase.Workdir, testCase.Env, debug)
+		cancel()
+		if len(setupFailures) > 0 {
+			for i, f := range setupFailures {
+				setupFailures[i] = "setup: " + f
+			}
+			return Result{
+				Status:   "ERRORED",
+				Name:     name,
+				Failures: append(setupFailures, runTeardown()...),
+			}
+		}
+	}
 
+	res := runTestAttempts(ctx, testCase, eng, image, defaultTimeout, debug, dryRun)
+	res.Failures = append(res.Failures, runTeardown()...)
+	if len(res.Failures) > 0 && res.Status == "PASSED" {
+		res.Status = "FAILED"
+	}
+	return res
+}
+
+// RunHooks runs a sequence of setup/teardown commands, in order, through eng
+// inside a short-lived container of image, stopping at the first failure.
+// It returns the combined stdout/stderr of every hook that ran and any
+// failure message.
+func RunHooks(ctx context.Context, eng engine.Engine, image string, hooks []config.CommandValue, workdir string, env map[string]string, debug bool) (stdout, stderr string, failures []string) {
+	for i, hook := range hooks {
+		spec := engine.RunSpec{Image: image, Command: []string(hook), Workdir: workdir, Env: env}
+		if debug {
+			fmt.Printf("[debug] hook %d/%d: %s\n", i+1, len(hooks), eng.Describe(spec))
+		}
+		result, err := eng.Run(ctx, spec)
+		stdout += result.Stdout
+		stderr += result.Stderr
+
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				failures = append(failures, fmt.Sprintf("hook %d/%d timed out", i+1, len(hooks)))
+			} else {
+				failures = append(failures, fmt.Sprintf("hook %d/%d: %v", i+1, len(hooks), err))
+			}
+			return stdout, stderr, failures
+		}
+		if result.ExitCode != 0 {
+			failures = append(failures, fmt.Sprintf("hook %d/%d exited %d", i+1, len(hooks), result.ExitCode))
+			return stdout, stderr, failures
+		}
+	}
+	return stdout, stderr, failures
+}
+
+// runTestAttempts executes testCase's main command (with retries per
+// testCase.Retry), without any setup/teardown bookkeeping. If testCase.Retry
+// is set, a failing attempt is re-executed up to Retry.Max times (subject to
+// Retry.On); Result.Attempts records every attempt, and Result.Status is
+// "FLAKY" rather than "PASSED" if a retry was needed.
+func runTestAttempts(ctx context.Context, testCase config.TestCase, eng engine.Engine, image string, defaultTimeout int, debug, dryRun bool) Result {
 	command := testCase.Exec
 	if len(command) == 0 {
 		command = testCase.Command
@@ -129,9 +461,58 @@ func RunSingleTest(testCase config.TestCase, engine, image string, defaultTimeou
 		}
 	}
 
-	runArgs := testCase.RunArgs
-	entrypoint := testCase.Entrypoint
+	maxAttempts := 1
+	var retryDelay time.Duration
+	var retryOn []string
+	if testCase.Retry != nil && testCase.Retry.Max > 0 {
+		maxAttempts += testCase.Retry.Max
+		retryDelay = time.Duration(testCase.Retry.DelaySeconds) * time.Second
+		retryOn = testCase.Retry.On
+	}
+
+	var attempts []AttemptResult
+	var res Result
+	for i := 0; i < maxAttempts; i++ {
+		if i > 0 && retryDelay > 0 {
+			timer := time.NewTimer(retryDelay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return Result{
+					Status:   "FAILED",
+					Name:     firstNonEmpty(testCase.Name, "unnamed"),
+					Failures: []string{"cancelled while waiting to retry"},
+					Attempts: attempts,
+				}
+			}
+		}
+
+		res = runAttempt(ctx, testCase, command, eng, image, defaultTimeout, debug, dryRun)
+		attempts = append(attempts, AttemptResult{
+			Stdout:   res.Stdout,
+			Stderr:   res.Stderr,
+			ExitCode: res.ExitCode,
+			Failures: res.Failures,
+		})
+
+		if len(res.Failures) == 0 || !retryTriggered(res.Failures, retryOn) {
+			break
+		}
+	}
+
+	if len(attempts) > 1 && len(res.Failures) == 0 {
+		res.Status = "FLAKY"
+	}
+	if len(attempts) > 1 || testCase.Retry != nil {
+		res.Attempts = attempts
+	}
+	return res
+}
 
+// runAttempt executes a single container run and evaluates expectations,
+// without any retry bookkeeping.
+func runAttempt(ctx context.Context, testCase config.TestCase, command config.CommandValue, eng engine.Engine, image string, defaultTimeout int, debug, dryRun bool) Result {
 	timeout := defaultTimeout
 	if testCase.Expect.TimeoutSeconds != nil {
 		timeout = *testCase.Expect.TimeoutSeconds
@@ -140,11 +521,18 @@ func RunSingleTest(testCase config.TestCase, engine, image string, defaultTimeou
 		timeout = *testCase.Timeout
 	}
 
-	runCmd := BuildRunCommand(engine, image, command, testCase.Workdir, testCase.Env, runArgs, entrypoint)
+	spec := engine.RunSpec{
+		Image:      image,
+		Command:    command,
+		Workdir:    testCase.Workdir,
+		Env:        testCase.Env,
+		RunArgs:    testCase.RunArgs,
+		Entrypoint: testCase.Entrypoint,
+	}
 
 	// Handle dry-run mode
 	if dryRun {
-		fmt.Printf("[dry-run] %s\n", strings.Join(runCmd, " "))
+		fmt.Printf("[dry-run] %s\n", eng.Describe(spec))
 		return Result{
 			Status: "DRY-RUN",
 			Name:   firstNonEmpty(testCase.Name, "unnamed"),
@@ -152,59 +540,399 @@ func RunSingleTest(testCase config.TestCase, engine, image string, defaultTimeou
 	}
 
 	if debug {
-		fmt.Printf("[debug] running: %s (timeout=%ds)\n", strings.Join(runCmd, " "), timeout)
+		fmt.Printf("[debug] running: %s (timeout=%ds)\n", eng.Describe(spec), timeout)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
-	cmd := exec.CommandContext(ctx, runCmd[0], runCmd[1:]...)
-	var stdoutBuf, stderrBuf bytes.Buffer
-	cmd.Stdout = &stdoutBuf
-	cmd.Stderr = &stderrBuf
 
-	err := cmd.Run()
-	stdout := stdoutBuf.String()
-	stderr := stderrBuf.String()
-	exitCode := 0
+	start := time.Now()
+	runResult, err := eng.Run(ctx, spec)
+	durationMs := time.Since(start).Milliseconds()
+	stdout := runResult.Stdout
+	stderr := runResult.Stderr
+	exitCode := runResult.ExitCode
 
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
+		if errors.Is(err, context.DeadlineExceeded) {
 			return Result{
-				Status:   "FAILED",
-				Name:     firstNonEmpty(testCase.Name, "unnamed"),
-				Stdout:   stdout,
-				Stderr:   stderr,
-				ExitCode: nil,
-				Failures: []string{fmt.Sprintf("timed out after %ds", timeout)},
+				Status:     "FAILED",
+				Name:       firstNonEmpty(testCase.Name, "unnamed"),
+				Stdout:     stdout,
+				Stderr:     stderr,
+				ExitCode:   nil,
+				Failures:   []string{fmt.Sprintf("timed out after %ds", timeout)},
+				DurationMs: durationMs,
 			}
 		}
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			exitCode = exitErr.ExitCode()
+		return Result{
+			Status:     "FAILED",
+			Name:       firstNonEmpty(testCase.Name, "unnamed"),
+			Stdout:     stdout,
+			Stderr:     stderr,
+			ExitCode:   nil,
+			Failures:   []string{fmt.Sprintf("exception: %v", err)},
+			DurationMs: durationMs,
+		}
+	}
+
+	var probeOutput *string
+	var probeFailures []string
+	if blocks := collectProbeBlocks(testCase.Expect); len(blocks) > 0 {
+		var script strings.Builder
+		for _, block := range blocks {
+			script.WriteString(buildProbeScript(block))
+			script.WriteString("\n")
+		}
+		out, probeErr := eng.Inspect(ctx, image, script.String())
+		if probeErr != nil {
+			probeFailures = []string{fmt.Sprintf("in-container probe failed: %v", probeErr)}
 		} else {
-			return Result{
-				Status:   "FAILED",
-				Name:     firstNonEmpty(testCase.Name, "unnamed"),
-				Stdout:   stdout,
-				Stderr:   stderr,
-				ExitCode: nil,
-				Failures: []string{fmt.Sprintf("exception: %v", err)},
-			}
+			probeOutput = &out
 		}
 	}
 
-	failures := evalExpectations(testCase.Expect, stdout, stderr, exitCode)
+	failures := evalExpectations(testCase.Expect, stdout, stderr, exitCode, durationMs, probeOutput)
+	failures = append(failures, probeFailures...)
+
 	status := "PASSED"
 	if len(failures) > 0 {
 		status = "FAILED"
 	}
 
 	return Result{
-		Status:   status,
-		Name:     firstNonEmpty(testCase.Name, "unnamed"),
-		Stdout:   stdout,
-		Stderr:   stderr,
-		ExitCode: &exitCode,
-		Failures: failures,
+		Status:     status,
+		Name:       firstNonEmpty(testCase.Name, "unnamed"),
+		Stdout:     stdout,
+		Stderr:     stderr,
+		ExitCode:   &exitCode,
+		Failures:   failures,
+		DurationMs: durationMs,
+	}
+}
+
+// retryTriggered reports whether any of failures matches a named retry
+// trigger ("timeout", "exit_code", "stderr_regex"). An empty triggers list
+// matches any failure.
+func retryTriggered(failures []string, triggers []string) bool {
+	if len(failures) == 0 {
+		return false
+	}
+	if len(triggers) == 0 {
+		return true
+	}
+	for _, f := range failures {
+		for _, t := range triggers {
+			switch t {
+			case "timeout":
+				if strings.HasPrefix(f, "timed out after") {
+					return true
+				}
+			case "exit_code":
+				if strings.HasPrefix(f, "exit code ") {
+					return true
+				}
+			case "stderr_regex":
+				if strings.HasPrefix(f, "stderr does not match regex") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// RunOptions configures RunAll's concurrency and execution behavior.
+type RunOptions struct {
+	Parallel       int
+	FailFast       bool
+	Engine         engine.Engine
+	Image          string
+	DefaultTimeout int
+	Debug          bool
+	DryRun         bool
+}
+
+// indexedResult pairs a Result with its position in the original test list.
+type indexedResult struct {
+	idx int
+	res Result
+}
+
+// RunAll runs tests through a worker pool of opts.Parallel goroutines
+// (defaulting to 1, preserving today's sequential behavior). The returned
+// []Result preserves original test ordering regardless of completion order.
+// onResult, if non-nil, is invoked once per completed test in completion
+// order; since it is only ever called from RunAll's single consumer
+// goroutine, callers can print per-test output there without it interleaving
+// across tests. If opts.FailFast is set, the first failing result cancels ctx
+// so in-flight exec.CommandContext runs are killed and queued tests are
+// skipped.
+func RunAll(ctx context.Context, tests []config.TestCase, opts RunOptions, onResult func(idx int, res Result)) []Result {
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]Result, len(tests))
+	jobs := make(chan int)
+	out := make(chan indexedResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				res := RunSingleTest(ctx, tests[idx], opts.Engine, opts.Image, opts.DefaultTimeout, opts.Debug, opts.DryRun)
+				out <- indexedResult{idx: idx, res: res}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for idx := range tests {
+			select {
+			case jobs <- idx:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	dispatched := make([]bool, len(tests))
+	for ir := range out {
+		dispatched[ir.idx] = true
+		results[ir.idx] = ir.res
+		if onResult != nil {
+			onResult(ir.idx, ir.res)
+		}
+		if opts.FailFast && len(ir.res.Failures) > 0 {
+			cancel()
+		}
+	}
+
+	// A fail-fast cancellation can leave some tests never started by a
+	// worker at all; give those an explicit "SKIPPED" result instead of the
+	// zero-value Result{} they'd otherwise keep, which report writers would
+	// otherwise render as an unnamed passing test.
+	for idx, ok := range dispatched {
+		if !ok {
+			results[idx] = Result{Status: "SKIPPED", Name: firstNonEmpty(tests[idx].Name, "unnamed")}
+		}
+	}
+
+	return results
+}
+
+// ServiceManager starts and stops the shared background containers declared
+// in a suite's top-level `services:` section, on a dedicated user-defined
+// network so dependent tests can reach them by service name.
+//
+// Unlike RunSingleTest/RunHooks, ServiceManager shells out to engine's CLI
+// directly ("network create", "run -d --network-alias", "exec", "rm -f")
+// instead of going through the engine.Engine abstraction, because
+// engine.Engine has no primitive for a detached, long-lived container on a
+// shared network — only one-shot Run/Inspect. `services:` is therefore a
+// docker-CLI-compatible-only feature (docker, podman, nerdctl); StartAll
+// rejects the kubectl backend rather than emit invalid flags.
+type ServiceManager struct {
+	engine  string
+	network string
+
+	mu      sync.Mutex
+	started []string // container names, in start order, for cleanup
+}
+
+// NewServiceManager creates a manager that runs services through engine (a
+// docker-compatible CLI name; see ServiceManager), joined to a network
+// uniquely named for this run.
+func NewServiceManager(engine, network string) *ServiceManager {
+	return &ServiceManager{engine: engine, network: network}
+}
+
+// Network returns the shared network dependent tests should join via
+// `--network` to reach services by name.
+func (m *ServiceManager) Network() string {
+	return m.network
+}
+
+// StartAll creates the shared network and starts every declared service in
+// order, waiting for each to report ready before starting the next. On any
+// failure it stops whatever it already started before returning the error.
+func (m *ServiceManager) StartAll(ctx context.Context, services []config.Service) error {
+	if len(services) == 0 {
+		return nil
+	}
+
+	// ServiceManager's docker-compatible "network create" / "--network-alias"
+	// flow has no kubectl equivalent (pods aren't started on a shared bridge
+	// network the way docker/podman/nerdctl containers are), so reject the
+	// combination outright rather than failing confusingly deep in a kubectl
+	// invocation.
+	if m.engine == "kubectl" {
+		return fmt.Errorf("services: is not supported with -engine kubectl")
+	}
+
+	if err := exec.CommandContext(ctx, m.engine, "network", "create", m.network).Run(); err != nil {
+		return fmt.Errorf("failed to create network %s: %w", m.network, err)
+	}
+
+	for _, svc := range services {
+		if err := m.start(ctx, svc); err != nil {
+			m.StopAll(context.Background())
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *ServiceManager) start(ctx context.Context, svc config.Service) error {
+	name := fmt.Sprintf("container-test-svc-%s-%d", svc.Name, time.Now().UnixNano())
+
+	args := []string{m.engine, "run", "-d", "--rm", "--name", name, "--network", m.network, "--network-alias", svc.Name}
+	for k, v := range svc.Env {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
+	}
+	args = append(args, svc.RunArgs...)
+	args = append(args, svc.Image)
+
+	if err := exec.CommandContext(ctx, args[0], args[1:]...).Run(); err != nil {
+		return fmt.Errorf("failed to start service %q: %w", svc.Name, err)
+	}
+
+	m.mu.Lock()
+	m.started = append(m.started, name)
+	m.mu.Unlock()
+
+	if svc.Ready == nil {
+		return nil
+	}
+	if err := m.waitReady(ctx, name, *svc.Ready); err != nil {
+		return fmt.Errorf("service %q: %w", svc.Name, err)
+	}
+	return nil
+}
+
+// waitReady polls probe.Exec inside containerName until its stdout contains
+// probe.StdoutContains, or probe.TimeoutSeconds elapses (default 10s).
+func (m *ServiceManager) waitReady(ctx context.Context, containerName string, probe config.ServiceReadyProbe) error {
+	timeout := probe.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = 10
+	}
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+
+	for {
+		args := append([]string{m.engine, "exec", containerName}, []string(probe.Exec)...)
+		var out bytes.Buffer
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stdout = &out
+		_ = cmd.Run()
+
+		if probe.StdoutContains == "" || strings.Contains(out.String(), probe.StdoutContains) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("did not become ready within %ds", timeout)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// StopAll removes every service container this manager started, plus the
+// shared network. It is safe to call more than once (e.g. from both a defer
+// and a signal handler) and ignores errors from already-gone containers so
+// cleanup proceeds even after a panic or Ctrl-C.
+func (m *ServiceManager) StopAll(ctx context.Context) {
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	for i := len(started) - 1; i >= 0; i-- {
+		_ = exec.CommandContext(ctx, m.engine, "rm", "-f", started[i]).Run()
+	}
+	_ = exec.CommandContext(ctx, m.engine, "network", "rm", m.network).Run()
+}
+
+// InjectServiceNetwork returns a copy of tests with `--network <network>`
+// appended to RunArgs for every test that declares `needs`, so it can reach
+// containers started by a ServiceManager on that network.
+func InjectServiceNetwork(tests []config.TestCase, network string) []config.TestCase {
+	if network == "" {
+		return tests
+	}
+	out := make([]config.TestCase, len(tests))
+	for i, t := range tests {
+		if len(t.Needs) > 0 {
+			t.RunArgs = append(append([]string{}, t.RunArgs...), "--network", network)
+		}
+		out[i] = t
+	}
+	return out
+}
+
+// SortByDependsOn orders tests so each follows everything named in its
+// depends_on, erroring on an unknown dependency name or a cycle. This fully
+// enforces depends_on under `-parallel 1`; at higher concurrency it only
+// affects submission order since RunAll's workers don't otherwise wait on
+// each other.
+func SortByDependsOn(tests []config.TestCase) ([]config.TestCase, error) {
+	index := make(map[string]int, len(tests))
+	for i, t := range tests {
+		if t.Name != "" {
+			index[t.Name] = i
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(tests))
+	order := make([]config.TestCase, 0, len(tests))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("depends_on cycle detected at %q", tests[i].Name)
+		}
+		state[i] = visiting
+		for _, dep := range tests[i].DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return fmt.Errorf("test %q depends_on unknown test %q", tests[i].Name, dep)
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		state[i] = done
+		order = append(order, tests[i])
+		return nil
+	}
+
+	for i := range tests {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
 	}
+	return order, nil
 }
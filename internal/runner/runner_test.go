@@ -0,0 +1,207 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"container-test-cli/internal/config"
+	"container-test-cli/internal/engine"
+)
+
+// fakeEngine returns a scripted sequence of Run results/errors, one per
+// call, holding the last entry for any call beyond the end of the sequence.
+type fakeEngine struct {
+	results []engine.Result
+	errs    []error
+	calls   int
+}
+
+func (f *fakeEngine) Name() string { return "fake" }
+
+func (f *fakeEngine) Run(ctx context.Context, spec engine.RunSpec) (engine.Result, error) {
+	i := f.calls
+	if i >= len(f.results) {
+		i = len(f.results) - 1
+	}
+	f.calls++
+	var err error
+	if i < len(f.errs) {
+		err = f.errs[i]
+	}
+	return f.results[i], err
+}
+
+func (f *fakeEngine) Pull(ctx context.Context, image string) error { return nil }
+
+func (f *fakeEngine) Inspect(ctx context.Context, image, script string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeEngine) Available(ctx context.Context) bool { return true }
+
+func (f *fakeEngine) Describe(spec engine.RunSpec) string { return "fake run" }
+
+func TestCheckAssertionsPass(t *testing.T) {
+	failures := checkAssertions(
+		[]string{`exit_code == 0`, `contains(stdout, "ready")`},
+		"service is ready\n", "", 0, 120,
+	)
+	if len(failures) != 0 {
+		t.Fatalf("expected no failures, got %v", failures)
+	}
+}
+
+func TestCheckAssertionsFailingExpression(t *testing.T) {
+	failures := checkAssertions([]string{`exit_code == 1`}, "", "", 0, 0)
+	if len(failures) != 1 {
+		t.Fatalf("expected exactly one failure, got %v", failures)
+	}
+}
+
+func TestCheckAssertionsMalformedExpression(t *testing.T) {
+	failures := checkAssertions([]string{`exit_code ===`}, "", "", 0, 0)
+	if len(failures) != 1 {
+		t.Fatalf("expected a compile-error failure, got %v", failures)
+	}
+}
+
+func TestCheckAssertionsEmpty(t *testing.T) {
+	if failures := checkAssertions(nil, "", "", 0, 0); failures != nil {
+		t.Fatalf("expected nil for no assertions, got %v", failures)
+	}
+}
+
+func TestEvalGroupOrPassesOnFirstMatch(t *testing.T) {
+	group := config.ExpectGroup{
+		BinOp: "or",
+		Tests: []config.ExpectBlock{
+			{StdoutContains: config.StringOrSlice{"nope"}},
+			{StdoutContains: config.StringOrSlice{"ready"}},
+		},
+	}
+	if failures := evalGroup(group, "service is ready\n", "", 0, 0, nil); len(failures) != 0 {
+		t.Fatalf("expected the or group to pass, got %v", failures)
+	}
+}
+
+func TestEvalGroupOrFailsWhenNoAlternativeMatches(t *testing.T) {
+	group := config.ExpectGroup{
+		BinOp: "or",
+		Tests: []config.ExpectBlock{
+			{StdoutContains: config.StringOrSlice{"nope"}},
+			{StdoutContains: config.StringOrSlice{"also-nope"}},
+		},
+	}
+	if failures := evalGroup(group, "hello\n", "", 0, 0, nil); len(failures) == 0 {
+		t.Fatal("expected the or group to fail when no alternative matches")
+	}
+}
+
+func TestEvalGroupAndRequiresEveryChild(t *testing.T) {
+	group := config.ExpectGroup{
+		BinOp: "and",
+		Tests: []config.ExpectBlock{
+			{StdoutContains: config.StringOrSlice{"hello"}},
+			{StdoutContains: config.StringOrSlice{"nope"}},
+		},
+	}
+	failures := evalGroup(group, "hello\n", "", 0, 0, nil)
+	if len(failures) != 1 {
+		t.Fatalf("expected exactly the one failing child's failure, got %v", failures)
+	}
+}
+
+func TestEvalGroupAndEmptyIsVacuouslyTrue(t *testing.T) {
+	group := config.ExpectGroup{BinOp: "and"}
+	if failures := evalGroup(group, "", "", 0, 0, nil); failures != nil {
+		t.Fatalf("expected an empty and group to have no failures, got %v", failures)
+	}
+}
+
+func TestEvalGroupRecursesIntoSubGroups(t *testing.T) {
+	group := config.ExpectGroup{
+		BinOp: "and",
+		SubGroups: []config.ExpectGroup{
+			{
+				BinOp: "or",
+				Tests: []config.ExpectBlock{
+					{StdoutContains: config.StringOrSlice{"nope"}},
+					{StdoutContains: config.StringOrSlice{"ready"}},
+				},
+			},
+		},
+	}
+	if failures := evalGroup(group, "ready\n", "", 0, 0, nil); len(failures) != 0 {
+		t.Fatalf("expected the nested or sub-group to satisfy the outer and, got %v", failures)
+	}
+}
+
+func TestEvalExpectationsDispatchesToGroup(t *testing.T) {
+	expect := config.ExpectBlock{
+		Group: &config.ExpectGroup{
+			BinOp: "or",
+			Tests: []config.ExpectBlock{
+				{StdoutContains: config.StringOrSlice{"ready"}},
+			},
+		},
+	}
+	if failures := evalExpectations(expect, "ready\n", "", 0, 0, nil); len(failures) != 0 {
+		t.Fatalf("expected the group to pass, got %v", failures)
+	}
+}
+
+func TestRetryTriggeredEmptyTriggersMatchAnyFailure(t *testing.T) {
+	if !retryTriggered([]string{"exit code 1 != expected ==0"}, nil) {
+		t.Fatal("expected an empty triggers list to match any failure")
+	}
+}
+
+func TestRetryTriggeredNamedTriggerMustMatch(t *testing.T) {
+	if retryTriggered([]string{`stdout missing: "ready"`}, []string{"timeout"}) {
+		t.Fatal("expected a non-timeout failure not to trigger a timeout-only retry")
+	}
+	if !retryTriggered([]string{"timed out after 5s"}, []string{"timeout"}) {
+		t.Fatal("expected a timeout failure to trigger a timeout retry")
+	}
+}
+
+func TestRetryTriggeredNoFailures(t *testing.T) {
+	if retryTriggered(nil, []string{"timeout"}) {
+		t.Fatal("expected no failures to never trigger a retry")
+	}
+}
+
+func TestRunTestAttemptsMarksFlakyAfterSuccessfulRetry(t *testing.T) {
+	eng := &fakeEngine{results: []engine.Result{{ExitCode: 1}, {ExitCode: 0}}}
+	tc := config.TestCase{
+		Command: config.CommandValue{"sh", "-c", "true"},
+		Retry:   &config.RetryPolicy{Max: 1},
+	}
+	res := runTestAttempts(context.Background(), tc, eng, "alpine", 5, false, false)
+	if res.Status != "FLAKY" {
+		t.Fatalf("expected FLAKY status after a passing retry, got %q", res.Status)
+	}
+	if len(res.Attempts) != 2 {
+		t.Fatalf("expected 2 recorded attempts, got %d", len(res.Attempts))
+	}
+}
+
+func TestRunTestAttemptsStopsRetryDelayOnCancellation(t *testing.T) {
+	eng := &fakeEngine{results: []engine.Result{{ExitCode: 1}}}
+	tc := config.TestCase{
+		Command: config.CommandValue{"sh", "-c", "false"},
+		Retry:   &config.RetryPolicy{Max: 1, DelaySeconds: 60},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	res := runTestAttempts(ctx, tc, eng, "alpine", 5, false, false)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected cancellation to short-circuit the retry delay, took %s", elapsed)
+	}
+	if len(res.Failures) == 0 {
+		t.Fatal("expected a failure result when cancelled mid-retry")
+	}
+}
@@ -0,0 +1,153 @@
+// Package watch implements -watch mode: it keeps the CLI resident, observes
+// the YAML config file (plus any -watch-path extras, e.g. image build
+// outputs) for changes, and re-runs affected tests on each save instead of
+// requiring a fresh invocation per change.
+package watch
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"container-test-cli/internal/cli"
+	"container-test-cli/internal/config"
+	"container-test-cli/internal/runner"
+)
+
+// debounceWindow absorbs editor save bursts (e.g. write-then-rename) into a
+// single reload.
+const debounceWindow = 250 * time.Millisecond
+
+// RunFunc executes the given tests and returns their results.
+type RunFunc func(tests config.TestList) []runner.Result
+
+// Run watches cfg.ConfigPath and re-executes affected tests on each change
+// until the watcher's events or errors channel closes. Watcher errors are
+// printed and watching continues; Run installs no signal handler, so Ctrl-C
+// ends the process directly. One-shot mode's exit code semantics are
+// unaffected since Run never itself calls os.Exit.
+func Run(cfg *cli.CliConfig, runTests RunFunc) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(cfg.ConfigPath); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", cfg.ConfigPath, err)
+	}
+
+	watchPaths := make(map[string]bool, len(cfg.WatchPaths))
+	for _, p := range cfg.WatchPaths {
+		if err := watcher.Add(p); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", p, err)
+		}
+		watchPaths[p] = true
+	}
+
+	// Suite-level suite_setup/suite_teardown fixtures are only honored by the
+	// one-shot entry point; -watch reloads just the tests themselves.
+	prevTests, _, err := config.LoadTests(cfg.ConfigPath, cfg.Vars)
+	if err != nil {
+		return fmt.Errorf("failed to load tests: %w", err)
+	}
+
+	fmt.Printf("Watching %s (Ctrl-C to stop)\n", cfg.ConfigPath)
+	runTests(prevTests)
+
+	var debounce *time.Timer
+	// pendingFull is set when a debounced reload was triggered by a
+	// -watch-path (e.g. an image build output) rather than the config file
+	// itself, forcing a full re-run instead of just the diffed changes. It is
+	// only ever touched from this loop's goroutine, including from the
+	// debounce timer's own channel send, so no locking is needed.
+	var pendingFull bool
+	reload := make(chan struct{}, 1)
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if watchPaths[event.Name] {
+				pendingFull = true
+			}
+			if debounce == nil {
+				debounce = time.AfterFunc(debounceWindow, func() {
+					select {
+					case reload <- struct{}{}:
+					default:
+					}
+				})
+			} else {
+				debounce.Reset(debounceWindow)
+			}
+
+		case watchErr, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Printf("watch error: %v\n", watchErr)
+
+		case <-reload:
+			debounce = nil
+			full := pendingFull
+			pendingFull = false
+			// The editor may have replaced a watched file (rename+create);
+			// re-add every watch in case the old inode is gone.
+			_ = watcher.Add(cfg.ConfigPath)
+			for p := range watchPaths {
+				_ = watcher.Add(p)
+			}
+
+			newTests, _, err := config.LoadTests(cfg.ConfigPath, cfg.Vars)
+			if err != nil {
+				fmt.Printf("reload failed: %v\n", err)
+				continue
+			}
+
+			// A -watch-path change (e.g. a fresh image build) forces a full
+			// re-run, since every test's outcome may depend on the new image;
+			// a config-only change only re-runs tests whose definition changed.
+			toRun := newTests
+			if !full {
+				toRun = changedTests(prevTests, newTests)
+			}
+
+			if len(toRun) == 0 {
+				fmt.Println("--> no test changes detected")
+			} else if full {
+				fmt.Printf("--> watched build output changed, re-running all %d test(s)\n", len(toRun))
+				runTests(toRun)
+			} else {
+				fmt.Printf("--> re-running %d changed test(s)\n", len(toRun))
+				runTests(toRun)
+			}
+			prevTests = newTests
+		}
+	}
+}
+
+// changedTests returns the tests in next that are new or differ from their
+// same-named counterpart in prev.
+func changedTests(prev, next config.TestList) config.TestList {
+	prevByName := make(map[string]config.TestCase, len(prev))
+	for _, t := range prev {
+		prevByName[t.Name] = t
+	}
+
+	var changed config.TestList
+	for _, t := range next {
+		old, ok := prevByName[t.Name]
+		if !ok || !reflect.DeepEqual(old, t) {
+			changed = append(changed, t)
+		}
+	}
+	return changed
+}